@@ -7,17 +7,24 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/exec"
 	"runtime"
 	"runtime/pprof"
+	"strings"
 	"sync"
+	"time"
 	_ "time/tzdata"
 
 	_ "go.uber.org/automaxprocs"
 
 	"github.com/childe/gohangout/input"
 	"github.com/childe/gohangout/internal/config"
+	"github.com/childe/gohangout/internal/logging"
+	svc "github.com/childe/gohangout/internal/service"
 	"github.com/childe/gohangout/internal/signal"
+	"github.com/childe/gohangout/internal/tracing"
 	"github.com/childe/gohangout/topology"
+	"github.com/kardianos/service"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"k8s.io/klog/v2"
 )
@@ -39,6 +46,16 @@ var options = &struct {
 	prometheus string
 
 	exitWhenNil bool
+	oneshot     bool
+
+	drainTimeout time.Duration
+
+	tracingEndpoint           string
+	tracingProtocol           string
+	tracingSamplingRatio      float64
+	tracingServiceName        string
+	tracingResourceAttributes string
+	debugLog                  bool
 }{}
 
 var (
@@ -103,6 +120,7 @@ func buildPluginLink(config map[string]interface{}) (boxes []*input.InputBox, er
 				return
 			}
 			box.SetShutdownWhenNil(options.exitWhenNil)
+			box.SetOneShot(options.oneshot)
 			boxes = append(boxes, box)
 		}
 	}
@@ -130,7 +148,100 @@ func reload() {
 	go inputs.start()
 }
 
+// gracefulRestart performs a zero-downtime restart: it forks a new
+// gohangout process, handing it the current listening sockets (of inputs
+// that implement topology.Listener) via ExtraFiles and LISTEN_FDS, so the
+// new process can start accepting on them immediately. This process then
+// stops accepting new work but keeps processing in-flight events through
+// the filter/output chain, flushing output buffers, before exiting.
+func gracefulRestart() {
+	boxes := ([]*input.InputBox)(inputs)
+
+	var files []*os.File
+	for _, box := range boxes {
+		l, ok := box.Input().(topology.Listener)
+		if !ok {
+			continue
+		}
+		f, err := l.ListenerFile()
+		if err != nil {
+			klog.Errorf("graceful restart: could not export listener fd: %v", err)
+			continue
+		}
+		files = append(files, f)
+	}
+
+	cmd := exec.Command(os.Args[0], os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(), fmt.Sprintf("LISTEN_FDS=%d", len(files)))
+
+	if err := cmd.Start(); err != nil {
+		klog.Errorf("graceful restart: could not start new process: %v", err)
+		return
+	}
+	klog.Infof("graceful restart: started pid %d with %d inherited listener(s)", cmd.Process.Pid, len(files))
+
+	klog.Info("graceful restart: stop accepting new work in this process")
+	for _, box := range boxes {
+		box.StopAccepting()
+	}
+
+	// give in-flight events time to drain through the filter/output chain
+	// (including Kafka/Elasticsearch bulk flushes) before this process
+	// exits for good.
+	time.AfterFunc(options.drainTimeout, func() {
+		klog.Info("graceful restart: drain complete, stopping this process")
+		exit()
+	})
+}
+
+// parseResourceAttributes parses the -tracing-resource-attributes flag,
+// "key1=value1,key2=value2", into the map tracing.Config.ResourceAttributes
+// expects. Malformed entries (missing "=") are logged as errors and skipped
+// rather than failing startup over a single bad attribute.
+func parseResourceAttributes(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+
+	attrs := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			klog.Errorf("invalid -tracing-resource-attributes entry %q, want key=value", kv)
+			continue
+		}
+		attrs[k] = v
+	}
+	return attrs
+}
+
 func _main() {
+	if err := logging.Init(options.debugLog); err != nil {
+		klog.Fatalf("could not init structured logger: %v", err)
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Endpoint:           options.tracingEndpoint,
+		Protocol:           options.tracingProtocol,
+		SamplingRatio:      options.tracingSamplingRatio,
+		ServiceName:        options.tracingServiceName,
+		ResourceAttributes: parseResourceAttributes(options.tracingResourceAttributes),
+	})
+	if err != nil {
+		klog.Fatalf("could not init tracing: %v", err)
+	}
+	if shutdownTracing != nil {
+		defer func() {
+			if err := shutdownTracing(context.Background()); err != nil {
+				klog.Errorf("could not shutdown tracing: %v", err)
+			}
+		}()
+	}
+
 	gohangoutConfig, err := config.ParseConfig(options.config)
 	if err != nil {
 		klog.Fatalf("could not parse config: %v", err)
@@ -144,6 +255,19 @@ func _main() {
 	defer cancel()
 
 	inputs = gohangoutInputs(boxes)
+
+	if options.oneshot {
+		for _, box := range boxes {
+			if !box.SupportsOneShot() {
+				klog.Fatalf("--oneshot requires every configured input to support one-shot acquisition; %T does not (it only tails)", box.Input())
+			}
+		}
+		klog.Info("oneshot mode: replaying available historical data through the topology, then exiting")
+		inputs.start()
+		inputs.stop()
+		return
+	}
+
 	go inputs.start()
 
 	if options.autoReload {
@@ -152,13 +276,51 @@ func _main() {
 		}
 	}
 
-	go signal.ListenSignal(exit, reload)
+	go signal.ListenSignal(exit, reload, gracefulRestart)
 
 	<-ctx.Done()
 	inputs.stop()
 }
 
+// serviceActions are the `gohangout service <action>` subcommands handled
+// by runServiceCommand instead of the normal flag-parsing startup path.
+var serviceActions = map[string]bool{
+	"install":   true,
+	"uninstall": true,
+	"start":     true,
+	"stop":      true,
+	"restart":   true,
+	"status":    true,
+}
+
+// runServiceCommand handles `gohangout service <action> [--config ...]`,
+// registering/controlling gohangout as a systemd/SCM/launchd service
+// without requiring the operator to hand-write a unit file.
+func runServiceCommand(args []string) {
+	if len(args) == 0 || !serviceActions[args[0]] {
+		fmt.Fprintln(os.Stderr, "usage: gohangout service install|uninstall|start|stop|restart|status [--config path] [--worker n] [--prometheus addr]")
+		os.Exit(2)
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("gohangout service "+action, flag.ExitOnError)
+	cfg := svc.Config{}
+	fs.StringVar(&cfg.ConfigPath, "config", "", "path to configuration file or directory, baked into the installed service definition")
+	fs.IntVar(&cfg.Worker, "worker", 1, "worker thread count, baked into the installed service definition")
+	fs.StringVar(&cfg.Prometheus, "prometheus", "", "address to expose prometheus metrics, baked into the installed service definition")
+	fs.Parse(args[1:])
+
+	if err := svc.Control(cfg, action); err != nil {
+		klog.Fatalf("service %s failed: %v", action, err)
+	}
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "service" {
+		runServiceCommand(os.Args[2:])
+		return
+	}
+
 	flag.StringVar(&options.config, "config", options.config, "path to configuration file or directory")
 	flag.BoolVar(&options.autoReload, "reload", options.autoReload, "if auto reload while config file changed")
 
@@ -172,6 +334,16 @@ func main() {
 	flag.StringVar(&options.prometheus, "prometheus", "", "address to expose prometheus metrics")
 
 	flag.BoolVar(&options.exitWhenNil, "exit-when-nil", false, "triger gohangout to exit when receive a nil event")
+	flag.BoolVar(&options.oneshot, "oneshot", false, "read all currently-available historical data through the topology, then exit, instead of tailing indefinitely")
+
+	flag.DurationVar(&options.drainTimeout, "drain-timeout", 30*time.Second, "how long to wait for in-flight events to drain during a graceful restart (SIGHUP) before this process exits")
+
+	flag.StringVar(&options.tracingEndpoint, "tracing-endpoint", "", "OTLP collector endpoint (host:port) to export traces to; tracing is disabled if empty")
+	flag.StringVar(&options.tracingProtocol, "tracing-protocol", "grpc", "OTLP exporter protocol: grpc or http")
+	flag.Float64Var(&options.tracingSamplingRatio, "tracing-sampling-ratio", 1.0, "fraction of traces to sample, in (0, 1]; 0 or a negative value samples every trace")
+	flag.StringVar(&options.tracingServiceName, "tracing-service-name", "gohangout", "service.name resource attribute reported to the tracing backend")
+	flag.StringVar(&options.tracingResourceAttributes, "tracing-resource-attributes", "", "comma-separated key=value resource attributes attached to every span, e.g. env=prod,region=us-east-1")
+	flag.BoolVar(&options.debugLog, "debug-log", false, "use a development (human-readable, verbose) structured logger instead of the production one")
 
 	klog.InitFlags(nil)
 	flag.Parse()
@@ -221,8 +393,33 @@ func main() {
 		}()
 	}
 
-	_main()
+	if service.Interactive() {
+		_main()
+		return
+	}
+
+	// Running under a service manager (systemd/SCM/launchd): reroute klog
+	// to the platform-appropriate sink and let kardianos/service drive the
+	// Start/Stop lifecycle so a managed stop/restart goes through the same
+	// exit()/cancel() drain path as SIGTERM.
+	gohangoutService, err := svc.New(svc.Config{
+		ConfigPath: options.config,
+		Worker:     *worker,
+		Prometheus: options.prometheus,
+	}, _main, exit)
+	if err != nil {
+		klog.Fatalf("could not build service definition: %v", err)
+	}
 
+	if w, err := svc.Logger(gohangoutService); err == nil {
+		klog.SetOutput(w)
+	} else {
+		klog.Errorf("could not attach service logger, keeping default klog output: %v", err)
+	}
+
+	if err := gohangoutService.Run(); err != nil {
+		klog.Fatalf("service run error: %v", err)
+	}
 }
 
 func exit() {
@@ -0,0 +1,399 @@
+package input
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/golang/glog"
+)
+
+// cwCheckpoint is the per-stream position persisted to the checkpoint file,
+// analogous to the sincedb used by the file input.
+type cwCheckpoint struct {
+	NextForwardToken string `json:"next_forward_token"`
+}
+
+// CloudWatchLogsInput tails one or more CloudWatch Logs streams and emits
+// each log event as a gohangout event.
+type CloudWatchLogsInput struct {
+	config map[interface{}]interface{}
+
+	logGroupName   string
+	logGroupPrefix string
+	streamPattern  *regexp.Regexp
+	pollInterval   time.Duration
+	startPosition  string // beginning|end|<unix_timestamp_ms>
+
+	useStreamIterator     bool
+	streamDiscoveryPeriod time.Duration
+
+	checkpointFile string
+	checkpointMu   sync.Mutex
+	checkpoints    map[string]*cwCheckpoint
+
+	client *cloudwatchlogs.Client
+
+	events chan map[string]interface{}
+
+	streamsMu     sync.Mutex
+	trackedStream map[string]bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewCloudWatchLogsInput builds a CloudWatchLogsInput from its config block.
+func NewCloudWatchLogsInput(config map[interface{}]interface{}) *CloudWatchLogsInput {
+	i := &CloudWatchLogsInput{
+		config:        config,
+		pollInterval:  10 * time.Second,
+		startPosition: "end",
+		checkpoints:   make(map[string]*cwCheckpoint),
+		events:        make(chan map[string]interface{}, 2048),
+		trackedStream: make(map[string]bool),
+	}
+
+	if v, ok := config["log_group"]; ok {
+		i.logGroupName = v.(string)
+	}
+	if v, ok := config["log_group_prefix"]; ok {
+		i.logGroupPrefix = v.(string)
+	}
+	if i.logGroupName == "" && i.logGroupPrefix == "" {
+		glog.Error("CloudWatchLogs input needs either `log_group` or `log_group_prefix`")
+		return nil
+	}
+
+	if v, ok := config["stream_name_pattern"]; ok {
+		re, err := regexp.Compile(v.(string))
+		if err != nil {
+			glog.Errorf("invalid stream_name_pattern: %v", err)
+			return nil
+		}
+		i.streamPattern = re
+	}
+
+	if v, ok := config["start_position"]; ok {
+		i.startPosition = fmt.Sprintf("%v", v)
+	}
+
+	if v, ok := config["poll_interval"]; ok {
+		i.pollInterval = time.Duration(v.(int)) * time.Second
+	}
+
+	if v, ok := config["use_stream_iterator"]; ok {
+		i.useStreamIterator = v.(bool)
+	}
+	i.streamDiscoveryPeriod = i.pollInterval
+	if v, ok := config["stream_discovery_interval"]; ok {
+		i.streamDiscoveryPeriod = time.Duration(v.(int)) * time.Second
+	}
+
+	if v, ok := config["checkpoint_file"]; ok {
+		i.checkpointFile = v.(string)
+	} else {
+		name := i.logGroupName
+		if name == "" {
+			name = i.logGroupPrefix
+		}
+		i.checkpointFile = fmt.Sprintf(".gohangout.cloudwatchlogs.%s.checkpoint", name)
+	}
+	i.loadCheckpoints()
+
+	optFns := []func(*awsconfig.LoadOptions) error{}
+	if v, ok := config["region"]; ok {
+		optFns = append(optFns, awsconfig.WithRegion(v.(string)))
+	}
+	if v, ok := config["profile"]; ok {
+		optFns = append(optFns, awsconfig.WithSharedConfigProfile(v.(string)))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(), optFns...)
+	if err != nil {
+		glog.Errorf("could not load AWS config: %v", err)
+		return nil
+	}
+
+	if v, ok := config["assume_role_arn"]; ok {
+		stsClient := sts.NewFromConfig(cfg)
+		cfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClient, v.(string)))
+	}
+
+	var clientOptFns []func(*cloudwatchlogs.Options)
+	if v, ok := config["endpoint"]; ok {
+		endpoint := v.(string)
+		clientOptFns = append(clientOptFns, func(o *cloudwatchlogs.Options) {
+			o.BaseEndpoint = aws.String(endpoint)
+		})
+	}
+	i.client = cloudwatchlogs.NewFromConfig(cfg, clientOptFns...)
+
+	i.ctx, i.cancel = context.WithCancel(context.Background())
+
+	if i.useStreamIterator {
+		i.wg.Add(1)
+		go i.discoverStreams()
+	} else {
+		// Without the iterator, take a one-time snapshot of the matching
+		// streams at startup and tail each with its own GetLogEvents
+		// checkpoint; new streams created afterwards are only picked up
+		// in use_stream_iterator mode.
+		i.discoverOnce()
+	}
+
+	return i
+}
+
+// loadCheckpoints reads the persisted per-stream nextForwardToken, if any.
+func (i *CloudWatchLogsInput) loadCheckpoints() {
+	data, err := os.ReadFile(i.checkpointFile)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &i.checkpoints); err != nil {
+		glog.Errorf("could not parse checkpoint file %s: %v", i.checkpointFile, err)
+	}
+}
+
+// flushCheckpoints writes the current per-stream tokens to disk.
+func (i *CloudWatchLogsInput) flushCheckpoints() {
+	i.checkpointMu.Lock()
+	defer i.checkpointMu.Unlock()
+
+	data, err := json.Marshal(i.checkpoints)
+	if err != nil {
+		glog.Errorf("could not marshal checkpoints: %v", err)
+		return
+	}
+	if err := os.WriteFile(i.checkpointFile, data, 0644); err != nil {
+		glog.Errorf("could not write checkpoint file %s: %v", i.checkpointFile, err)
+	}
+}
+
+// discoverStreams periodically lists log streams matching streamPattern and
+// starts a poller goroutine for each new one.
+func (i *CloudWatchLogsInput) discoverStreams() {
+	defer i.wg.Done()
+
+	ticker := time.NewTicker(i.streamDiscoveryPeriod)
+	defer ticker.Stop()
+
+	i.discoverOnce()
+	for {
+		select {
+		case <-i.ctx.Done():
+			return
+		case <-ticker.C:
+			i.discoverOnce()
+		}
+	}
+}
+
+// discoverLogGroups resolves the configured log_group/log_group_prefix into
+// the concrete log group names to track. A direct log_group short-circuits
+// the lookup; log_group_prefix is resolved via DescribeLogGroups, paging
+// through its NextToken until exhausted, so every matching group is found
+// regardless of how many there are.
+func (i *CloudWatchLogsInput) discoverLogGroups() ([]string, error) {
+	if i.logGroupName != "" {
+		return []string{i.logGroupName}, nil
+	}
+
+	var groups []string
+	var nextToken *string
+	for {
+		out, err := i.client.DescribeLogGroups(i.ctx, &cloudwatchlogs.DescribeLogGroupsInput{
+			LogGroupNamePrefix: aws.String(i.logGroupPrefix),
+			NextToken:          nextToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range out.LogGroups {
+			groups = append(groups, aws.ToString(g.LogGroupName))
+		}
+		if out.NextToken == nil {
+			break
+		}
+		nextToken = out.NextToken
+	}
+	return groups, nil
+}
+
+func (i *CloudWatchLogsInput) discoverOnce() {
+	groups, err := i.discoverLogGroups()
+	if err != nil {
+		glog.Errorf("could not resolve log groups for prefix %q: %v", i.logGroupPrefix, err)
+		return
+	}
+
+	i.streamsMu.Lock()
+	defer i.streamsMu.Unlock()
+
+	for _, group := range groups {
+		var nextToken *string
+		for {
+			out, err := i.client.DescribeLogStreams(i.ctx, &cloudwatchlogs.DescribeLogStreamsInput{
+				LogGroupName: aws.String(group),
+				NextToken:    nextToken,
+			})
+			if err != nil {
+				glog.Errorf("DescribeLogStreams(%s) error: %v", group, err)
+				break
+			}
+
+			for _, s := range out.LogStreams {
+				name := aws.ToString(s.LogStreamName)
+				if i.streamPattern != nil && !i.streamPattern.MatchString(name) {
+					continue
+				}
+				key := group + "/" + name
+				if i.trackedStream[key] {
+					continue
+				}
+				i.trackedStream[key] = true
+				i.wg.Add(1)
+				go i.pollStream(group, name)
+			}
+
+			if out.NextToken == nil {
+				break
+			}
+			nextToken = out.NextToken
+		}
+	}
+}
+
+// pollStream polls a single stream with GetLogEvents, checkpointing its
+// nextForwardToken so a restart resumes from where it left off. Used for
+// every discovered stream, in both use_stream_iterator and one-time
+// discovery mode.
+func (i *CloudWatchLogsInput) pollStream(group, streamName string) {
+	defer i.wg.Done()
+
+	ticker := time.NewTicker(i.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-i.ctx.Done():
+			return
+		case <-ticker.C:
+			i.getLogEventsOnce(group, streamName)
+		}
+	}
+}
+
+func (i *CloudWatchLogsInput) getLogEventsOnce(group, streamName string) {
+	key := group + "/" + streamName
+	token := i.nextToken(key)
+
+	input := &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(group),
+		LogStreamName: aws.String(streamName),
+		StartFromHead: aws.Bool(true),
+	}
+	if token != "" {
+		input.NextToken = aws.String(token)
+	} else {
+		input.StartTime = aws.Int64(i.startTimeMillis())
+	}
+
+	out, err := i.client.GetLogEvents(i.ctx, input)
+	if err != nil {
+		glog.Errorf("GetLogEvents(%s/%s) error: %v", group, streamName, err)
+		return
+	}
+
+	for _, e := range out.Events {
+		i.emit(group, streamName, types.FilteredLogEvent{
+			Message:   e.Message,
+			Timestamp: e.Timestamp,
+		})
+	}
+	if out.NextForwardToken != nil && aws.ToString(out.NextForwardToken) != token {
+		i.setToken(key, aws.ToString(out.NextForwardToken))
+		i.flushCheckpoints()
+	}
+}
+
+func (i *CloudWatchLogsInput) startTimeMillis() int64 {
+	switch i.startPosition {
+	case "beginning":
+		return 0
+	case "end":
+		return time.Now().UnixMilli()
+	default:
+		var ts int64
+		if _, err := fmt.Sscanf(i.startPosition, "%d", &ts); err == nil {
+			return ts
+		}
+		return time.Now().UnixMilli()
+	}
+}
+
+func (i *CloudWatchLogsInput) nextToken(key string) string {
+	i.checkpointMu.Lock()
+	defer i.checkpointMu.Unlock()
+	if cp, ok := i.checkpoints[key]; ok {
+		return cp.NextForwardToken
+	}
+	return ""
+}
+
+func (i *CloudWatchLogsInput) setToken(key, token string) {
+	i.checkpointMu.Lock()
+	defer i.checkpointMu.Unlock()
+	i.checkpoints[key] = &cwCheckpoint{NextForwardToken: token}
+}
+
+func (i *CloudWatchLogsInput) emit(group, streamName string, e types.FilteredLogEvent) {
+	event := map[string]interface{}{
+		"message":        aws.ToString(e.Message),
+		"log_group":      group,
+		"log_stream":     streamName,
+		"ingestion_time": time.Now().Unix(),
+	}
+	if e.Timestamp != nil {
+		event["@timestamp"] = time.UnixMilli(*e.Timestamp)
+	}
+
+	select {
+	case i.events <- event:
+	case <-i.ctx.Done():
+	}
+}
+
+// ReadOneEvent implements topology.Input.
+func (i *CloudWatchLogsInput) ReadOneEvent() map[string]interface{} {
+	select {
+	case e, ok := <-i.events:
+		if !ok {
+			return nil
+		}
+		return e
+	case <-i.ctx.Done():
+		return nil
+	}
+}
+
+// Shutdown implements topology.Input. It stops all pollers, flushes the
+// final checkpoint tokens, and closes the event channel.
+func (i *CloudWatchLogsInput) Shutdown() {
+	i.cancel()
+	i.wg.Wait()
+	i.flushCheckpoints()
+	close(i.events)
+}
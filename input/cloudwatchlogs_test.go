@@ -0,0 +1,42 @@
+package input
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCloudWatchLogsCheckpointRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	i := &CloudWatchLogsInput{
+		checkpointFile: path,
+		checkpoints:    make(map[string]*cwCheckpoint),
+	}
+	i.setToken("mygroup/mystream", "f/1234567890")
+	i.flushCheckpoints()
+
+	reloaded := &CloudWatchLogsInput{
+		checkpointFile: path,
+		checkpoints:    make(map[string]*cwCheckpoint),
+	}
+	reloaded.loadCheckpoints()
+
+	if got := reloaded.nextToken("mygroup/mystream"); got != "f/1234567890" {
+		t.Fatalf("got token %q, want %q", got, "f/1234567890")
+	}
+	if got := reloaded.nextToken("mygroup/other-stream"); got != "" {
+		t.Fatalf("got token %q for untracked stream, want empty", got)
+	}
+}
+
+func TestCloudWatchLogsLoadCheckpointsMissingFile(t *testing.T) {
+	i := &CloudWatchLogsInput{
+		checkpointFile: filepath.Join(t.TempDir(), "does-not-exist.json"),
+		checkpoints:    make(map[string]*cwCheckpoint),
+	}
+	i.loadCheckpoints()
+
+	if got := i.nextToken("mygroup/mystream"); got != "" {
+		t.Fatalf("got token %q, want empty for a missing checkpoint file", got)
+	}
+}
@@ -0,0 +1,73 @@
+package input
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/childe/gohangout/topology"
+)
+
+// legacyInputAdapter adapts the pull-based topology.Input interface
+// (ReadOneEvent/Shutdown) to topology.DataSource, so existing inputs keep
+// working unchanged against the shared acquisition engine in InputBox.beat.
+type legacyInputAdapter struct {
+	input topology.Input
+}
+
+func newLegacyInputAdapter(input topology.Input) *legacyInputAdapter {
+	return &legacyInputAdapter{input: input}
+}
+
+// dataSourceFor returns in as a topology.DataSource directly if it already
+// implements one (a native source that declares real one-shot support),
+// otherwise wraps it in the streaming-only legacyInputAdapter.
+func dataSourceFor(in topology.Input) topology.DataSource {
+	if ds, ok := in.(topology.DataSource); ok {
+		return ds
+	}
+	return newLegacyInputAdapter(in)
+}
+
+// Configure is a no-op: legacy inputs are already configured by their
+// New*Input constructor.
+func (a *legacyInputAdapter) Configure(config map[interface{}]interface{}) error {
+	return nil
+}
+
+func (a *legacyInputAdapter) CanRun() bool {
+	return a.input != nil
+}
+
+// Mode reports ModeStreaming: legacy inputs have no notion of "replay
+// historical data then stop", they just read until ReadOneEvent returns
+// nil.
+func (a *legacyInputAdapter) Mode() topology.Mode {
+	return topology.ModeStreaming
+}
+
+func (a *legacyInputAdapter) OneShotAcquisition(ctx context.Context, out chan<- topology.Event) error {
+	return fmt.Errorf("%T does not support one-shot acquisition", a.input)
+}
+
+// StreamingAcquisition pulls events off the legacy input with ReadOneEvent
+// until it returns nil (the input is exhausted/shut down) or ctx is done.
+func (a *legacyInputAdapter) StreamingAcquisition(ctx context.Context, out chan<- topology.Event) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		event := a.input.ReadOneEvent()
+		if event == nil {
+			return nil
+		}
+
+		select {
+		case out <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package input
+
+import (
+	"github.com/childe/gohangout/topology"
+	"github.com/golang/glog"
+)
+
+// GetInput builds an input plugin instance from its type name and config.
+// It returns nil if the type is unknown or the plugin fails to initialize.
+func GetInput(inputType string, config map[interface{}]interface{}) topology.Input {
+	switch inputType {
+	case "CloudWatchLogs":
+		if i := NewCloudWatchLogsInput(config); i != nil {
+			return i
+		}
+		return nil
+	case "Syslog":
+		if s := NewSyslogInput(config); s != nil {
+			return s
+		}
+		return nil
+	default:
+		glog.Errorf("could not find input plugin %s", inputType)
+		return nil
+	}
+}
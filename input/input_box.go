@@ -1,14 +1,45 @@
 package input
 
 import (
+	"context"
+	"fmt"
 	"sync"
 
 	"github.com/childe/gohangout/filter"
+	"github.com/childe/gohangout/internal/logging"
 	"github.com/childe/gohangout/output"
 	"github.com/childe/gohangout/topology"
 	"github.com/golang/glog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
+// acquisitionChannelBufferSize bounds the channel each worker's
+// DataSource.*Acquisition call feeds, giving uniform backpressure across
+// input types instead of each one inventing its own buffering.
+const acquisitionChannelBufferSize = 1024
+
+// eventCarrier lets an OpenTelemetry propagator read a W3C traceparent (and
+// tracestate) that an input protocol carrying one, e.g. HTTP or Kafka
+// headers, stashed into the event's fields, so the per-event span
+// continues the caller's trace instead of always starting a new one.
+type eventCarrier map[string]interface{}
+
+func (c eventCarrier) Get(key string) string {
+	if v, ok := c[key]; ok {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func (c eventCarrier) Set(key, value string) { c[key] = value }
+
+func (c eventCarrier) Keys() []string { return []string{"traceparent", "tracestate"} }
+
 type InputBox struct {
 	config             map[string]interface{} // whole config
 	input              topology.Input
@@ -16,6 +47,7 @@ type InputBox struct {
 	stop               bool
 	once               sync.Once
 	shutdownChan       chan bool
+	oneshot            bool
 }
 
 func NewInputBox(input topology.Input, config map[string]interface{}) *InputBox {
@@ -27,6 +59,23 @@ func NewInputBox(input topology.Input, config map[string]interface{}) *InputBox
 	}
 }
 
+// SetOneShot switches this box's workers to DataSource.OneShotAcquisition
+// instead of StreamingAcquisition: they read all currently-available
+// historical data and finish, rather than tailing indefinitely. Used by
+// the --oneshot CLI mode to reprocess archives.
+func (box *InputBox) SetOneShot(oneshot bool) {
+	box.oneshot = oneshot
+}
+
+// SupportsOneShot reports whether this box's underlying input can
+// actually satisfy --oneshot, i.e. its DataSource.Mode() is something
+// other than ModeStreaming. Legacy inputs (wrapped by the built-in
+// adapter) never do; a native input opts in by implementing
+// topology.DataSource itself.
+func (box *InputBox) SupportsOneShot() bool {
+	return dataSourceFor(box.input).Mode() != topology.ModeStreaming
+}
+
 func (box *InputBox) beat(workerIdx int) {
 	outputs := output.BuildOutputs(box.config)
 	box.outputsInAllWorker[workerIdx] = outputs
@@ -46,20 +95,61 @@ func (box *InputBox) beat(workerIdx int) {
 	}
 	firstNode = topology.AppendProcessorsToLink(firstNode, outputProcessor)
 
-	var (
-		event map[string]interface{}
-	)
+	inputName := fmt.Sprintf("%T", box.input)
+	tracer := otel.Tracer("github.com/childe/gohangout/input")
+	logger := logging.L.With(zap.String("input", inputName), zap.Int("worker", workerIdx))
+
+	dataSource := dataSourceFor(box.input)
+	events := make(chan topology.Event, acquisitionChannelBufferSize)
+
+	acqCtx, acqCancel := context.WithCancel(context.Background())
+	defer acqCancel()
+
+	go func() {
+		defer close(events)
+
+		if box.oneshot && dataSource.Mode() == topology.ModeStreaming {
+			// This source only implements tailing: running it under
+			// --oneshot would block forever instead of terminating once
+			// historical data is read, so fail fast instead of silently
+			// hanging. _main already refuses to start in --oneshot
+			// mode with such an input; this is a defensive
+			// backstop.
+			glog.Errorf("%s does not support one-shot acquisition, refusing to tail under --oneshot", inputName)
+			return
+		}
+
+		var err error
+		if box.oneshot {
+			err = dataSource.OneShotAcquisition(acqCtx, events)
+		} else {
+			err = dataSource.StreamingAcquisition(acqCtx, events)
+		}
+		if err != nil && err != context.Canceled {
+			glog.Errorf("%s acquisition error: %v", inputName, err)
+		}
+	}()
 
 	for !box.stop {
-		event = box.input.ReadOneEvent()
-		if event == nil {
+		event, ok := <-events
+		if !ok {
 			if !box.stop {
 				glog.Info("receive nil message. shutdown...")
 				box.shutdown()
 			}
 			return
 		}
-		firstNode.Process(event)
+
+		parentCtx := otel.GetTextMapPropagator().Extract(context.Background(), eventCarrier(event))
+		eventCtx, span := tracer.Start(parentCtx, "gohangout.process_event",
+			trace.WithAttributes(
+				attribute.String("input.type", inputName),
+				attribute.Int("worker.index", workerIdx),
+			),
+		)
+		logger.Debug("processing event", zap.String("trace_id", span.SpanContext().TraceID().String()))
+		firstNode.Process(eventCtx, event)
+		span.End()
 	}
 }
 
@@ -93,3 +183,28 @@ func (box *InputBox) Shutdown() {
 	box.shutdown()
 	box.stop = true
 }
+
+// Input returns the underlying input plugin, e.g. so callers can type-assert
+// it to topology.Listener to export a listening socket's fd.
+func (box *InputBox) Input() topology.Input {
+	return box.input
+}
+
+// stopAccepter is implemented by network-based inputs that can stop
+// admitting new work (new connections, new polls) while continuing to
+// deliver events already buffered or in flight to ReadOneEvent. It backs
+// graceful restart: the old process stops accepting as soon as the new
+// process is ready to take over the inherited listeners.
+type stopAccepter interface {
+	StopAccepting()
+}
+
+// StopAccepting tells the underlying input to stop admitting new work, if
+// it supports doing so, without stopping delivery of events already in
+// flight. Inputs that don't implement stopAccepter are left untouched;
+// call Shutdown to stop them.
+func (box *InputBox) StopAccepting() {
+	if sa, ok := box.input.(stopAccepter); ok {
+		sa.StopAccepting()
+	}
+}
@@ -0,0 +1,380 @@
+package input
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/childe/gohangout/topology"
+	"github.com/golang/glog"
+)
+
+// SyslogInput listens for syslog messages over UDP and TCP (optionally
+// TLS), parsing both RFC3164 and RFC5424 framing.
+type SyslogInput struct {
+	config map[interface{}]interface{}
+
+	listen   string
+	protocol string // udp|tcp
+	useTLS   bool
+	certFile string
+	keyFile  string
+
+	poolSize int
+
+	udpConn   *net.UDPConn
+	tcpListen net.Listener
+	rawTCP    *net.TCPListener // unwrapped listener, used to export the fd even when tcpListen is TLS-wrapped
+
+	events chan map[string]interface{}
+
+	connsMu sync.Mutex
+	conns   map[net.Conn]struct{}
+
+	stop     bool
+	stopMu   sync.Mutex
+	stopChan chan struct{} // closed only as a last-resort shutdown-timeout path, to unblock emit() sends that would otherwise stall teardown forever
+	wg       sync.WaitGroup
+	once     sync.Once
+}
+
+// NewSyslogInput builds a SyslogInput from its config block.
+func NewSyslogInput(config map[interface{}]interface{}) *SyslogInput {
+	s := &SyslogInput{
+		config:   config,
+		protocol: "udp",
+		poolSize: 32,
+		events:   make(chan map[string]interface{}, 2048),
+		conns:    make(map[net.Conn]struct{}),
+		stopChan: make(chan struct{}),
+	}
+
+	if v, ok := config["listen"]; ok {
+		s.listen = v.(string)
+	} else {
+		glog.Error("Syslog input needs `listen`")
+		return nil
+	}
+
+	if v, ok := config["protocol"]; ok {
+		s.protocol = v.(string)
+	}
+	if v, ok := config["tls"]; ok {
+		s.useTLS = v.(bool)
+	}
+	if v, ok := config["cert_file"]; ok {
+		s.certFile = v.(string)
+	}
+	if v, ok := config["key_file"]; ok {
+		s.keyFile = v.(string)
+	}
+	if v, ok := config["pool_size"]; ok {
+		s.poolSize = v.(int)
+	}
+
+	switch s.protocol {
+	case "udp":
+		if err := s.listenUDP(); err != nil {
+			glog.Errorf("could not listen udp %s: %v", s.listen, err)
+			return nil
+		}
+	case "tcp":
+		if err := s.listenTCP(); err != nil {
+			glog.Errorf("could not listen tcp %s: %v", s.listen, err)
+			return nil
+		}
+	default:
+		glog.Errorf("unknown syslog protocol %s", s.protocol)
+		return nil
+	}
+
+	return s
+}
+
+func (s *SyslogInput) listenUDP() error {
+	if f := topology.NextInheritedListener(); f != nil {
+		pc, err := net.FilePacketConn(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		conn, ok := pc.(*net.UDPConn)
+		if !ok {
+			return fmt.Errorf("inherited fd is not a UDP socket")
+		}
+		s.udpConn = conn
+		s.wg.Add(1)
+		go s.readUDP()
+		return nil
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", s.listen)
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return err
+	}
+	s.udpConn = conn
+
+	s.wg.Add(1)
+	go s.readUDP()
+	return nil
+}
+
+func (s *SyslogInput) readUDP() {
+	defer s.wg.Done()
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := s.udpConn.ReadFromUDP(buf)
+		if err != nil {
+			if s.isStopped() {
+				return
+			}
+			glog.Errorf("udp read error: %v", err)
+			continue
+		}
+		s.emit(parseSyslogMessage(string(buf[:n])))
+	}
+}
+
+func (s *SyslogInput) listenTCP() error {
+	var rawLn *net.TCPListener
+	var err error
+
+	if f := topology.NextInheritedListener(); f != nil {
+		ln, lerr := net.FileListener(f)
+		f.Close()
+		if lerr != nil {
+			return lerr
+		}
+		tln, ok := ln.(*net.TCPListener)
+		if !ok {
+			return fmt.Errorf("inherited fd is not a TCP socket")
+		}
+		rawLn = tln
+	} else {
+		addr, aerr := net.ResolveTCPAddr("tcp", s.listen)
+		if aerr != nil {
+			return aerr
+		}
+		rawLn, err = net.ListenTCP("tcp", addr)
+		if err != nil {
+			return err
+		}
+	}
+	s.rawTCP = rawLn
+
+	var ln net.Listener = rawLn
+	if s.useTLS {
+		cert, cerr := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+		if cerr != nil {
+			return cerr
+		}
+		ln = tls.NewListener(rawLn, &tls.Config{Certificates: []tls.Certificate{cert}})
+	}
+	s.tcpListen = ln
+
+	sem := make(chan struct{}, s.poolSize)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if s.isStopped() {
+					return
+				}
+				glog.Errorf("tcp accept error: %v", err)
+				continue
+			}
+
+			s.connsMu.Lock()
+			s.conns[conn] = struct{}{}
+			s.connsMu.Unlock()
+
+			sem <- struct{}{}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				defer func() { <-sem }()
+				s.handleConn(conn)
+			}()
+		}
+	}()
+
+	return nil
+}
+
+// handleConn reads framed syslog messages from a TCP connection, supporting
+// both non-transparent (newline-delimited) and octet-counting framing
+// (RFC6587), and drains until EOF so in-flight messages are not dropped on
+// shutdown.
+func (s *SyslogInput) handleConn(conn net.Conn) {
+	defer func() {
+		s.connsMu.Lock()
+		delete(s.conns, conn)
+		s.connsMu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	for {
+		msg, err := readFramedMessage(r)
+		if err != nil {
+			if err != io.EOF {
+				glog.Errorf("syslog tcp read error: %v", err)
+			}
+			return
+		}
+		s.emit(parseSyslogMessage(msg))
+	}
+}
+
+// readFramedMessage reads one message off r. If the stream starts with a
+// decimal digit, it is treated as octet-counting framing ("<len> <msg>");
+// otherwise it falls back to non-transparent, newline-delimited framing.
+func readFramedMessage(r *bufio.Reader) (string, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return "", err
+	}
+
+	if b[0] >= '0' && b[0] <= '9' {
+		lenStr, err := r.ReadString(' ')
+		if err != nil {
+			return "", err
+		}
+		n, err := strconv.Atoi(lenStr[:len(lenStr)-1])
+		if err != nil {
+			// not actually octet-counted, treat what we've read as part of a line
+			rest, rerr := r.ReadString('\n')
+			return lenStr + rest, rerr
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+
+	line, err := r.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return line, nil
+}
+
+// emit hands a parsed message to the events channel. It blocks under
+// backpressure rather than dropping; it only gives up, dropping the
+// message, once Shutdown's grace period has expired and stopChan is closed.
+func (s *SyslogInput) emit(fields map[string]interface{}, err error) {
+	if err != nil {
+		glog.Errorf("could not parse syslog message: %v", err)
+		return
+	}
+	select {
+	case s.events <- fields:
+	case <-s.stopChan:
+		glog.Warningf("syslog input shutting down, dropping already-received message: %v", fields)
+	}
+}
+
+func (s *SyslogInput) isStopped() bool {
+	s.stopMu.Lock()
+	defer s.stopMu.Unlock()
+	return s.stop
+}
+
+// closeListeners closes the listening socket(s) without touching
+// already-accepted connections, so accept loops return and readers keep
+// draining in-flight data.
+func (s *SyslogInput) closeListeners() {
+	s.stopMu.Lock()
+	s.stop = true
+	s.stopMu.Unlock()
+
+	if s.udpConn != nil {
+		s.udpConn.Close()
+	}
+	if s.tcpListen != nil {
+		s.tcpListen.Close()
+	}
+}
+
+// StopAccepting implements the input package's stopAccepter hook for
+// graceful restart: it stops admitting new connections/datagrams but
+// leaves already-accepted TCP connections running so they can drain.
+func (s *SyslogInput) StopAccepting() {
+	s.closeListeners()
+}
+
+// ListenerFile implements topology.Listener, exporting this input's
+// listening socket's fd so it can be inherited by a new process during a
+// graceful restart instead of the new process re-binding (and, for UDP,
+// racing the still-bound old process for the port). Exactly one of
+// rawTCP/udpConn is set, matching the listen/protocol this input was
+// configured with.
+func (s *SyslogInput) ListenerFile() (*os.File, error) {
+	if s.rawTCP != nil {
+		return s.rawTCP.File()
+	}
+	if s.udpConn != nil {
+		return s.udpConn.File()
+	}
+	return nil, fmt.Errorf("syslog input has no listening socket to export")
+}
+
+// ReadOneEvent implements topology.Input.
+func (s *SyslogInput) ReadOneEvent() map[string]interface{} {
+	e, ok := <-s.events
+	if !ok {
+		return nil
+	}
+	return e
+}
+
+// Shutdown implements topology.Input. It stops accepting new connections
+// and datagrams, gives in-flight TCP connections and pending emit() sends
+// up to 30s to drain normally, then closes the event channel. Messages are
+// only ever dropped if that grace period expires without the consumer
+// keeping up; a clean shutdown never drops an already-received message.
+func (s *SyslogInput) Shutdown() {
+	s.once.Do(func() {
+		s.closeListeners()
+
+		done := make(chan struct{})
+		go func() {
+			s.wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(30 * time.Second):
+			glog.Warning("syslog input shutdown timed out, force-closing connections and dropping any message still blocked on a full events channel")
+			s.connsMu.Lock()
+			for c := range s.conns {
+				c.Close()
+			}
+			s.connsMu.Unlock()
+
+			// Last resort only: a consumer that's stopped draining would
+			// otherwise hold wg.Wait here forever. Closing stopChan lets
+			// any emit() still blocked on a full channel give up.
+			close(s.stopChan)
+			<-done
+		}
+
+		close(s.events)
+	})
+}
@@ -0,0 +1,191 @@
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseSyslogMessage parses a single syslog message, trying RFC5424 first
+// and falling back to RFC3164 (BSD syslog). The returned fields are the
+// ones downstream filters consume: timestamp, hostname, appname, procid,
+// msgid, facility, severity, structured_data and message.
+func parseSyslogMessage(raw string) (map[string]interface{}, error) {
+	line := strings.TrimRight(raw, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty syslog message")
+	}
+
+	if len(line) > 0 && line[0] == '<' {
+		if fields, err := parseRFC5424(line); err == nil {
+			return fields, nil
+		}
+	}
+
+	return parseRFC3164(line)
+}
+
+// parsePriority reads the leading "<NNN>" priority value and splits it
+// into facility/severity, returning the value and the remainder of the
+// message.
+func parsePriority(line string) (facility, severity int, rest string, err error) {
+	if len(line) == 0 || line[0] != '<' {
+		return 0, 0, "", fmt.Errorf("missing priority")
+	}
+	end := strings.IndexByte(line, '>')
+	if end < 0 {
+		return 0, 0, "", fmt.Errorf("unterminated priority")
+	}
+	pri, err := strconv.Atoi(line[1:end])
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid priority: %w", err)
+	}
+	return pri / 8, pri % 8, line[end+1:], nil
+}
+
+// parseRFC5424 parses "<PRI>VERSION TIMESTAMP HOSTNAME APPNAME PROCID MSGID
+// STRUCTURED-DATA MSG".
+func parseRFC5424(line string) (map[string]interface{}, error) {
+	facility, severity, rest, err := parsePriority(line)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.SplitN(rest, " ", 7)
+	if len(parts) < 7 || parts[0] != "1" {
+		return nil, fmt.Errorf("not an RFC5424 message")
+	}
+	timestampS, hostname, appname, procid, msgid, tail := parts[1], parts[2], parts[3], parts[4], parts[5], parts[6]
+
+	sd, message := splitStructuredData(tail)
+
+	fields := map[string]interface{}{
+		"facility":        facility,
+		"severity":        severity,
+		"hostname":        unwrapNil(hostname),
+		"appname":         unwrapNil(appname),
+		"procid":          unwrapNil(procid),
+		"msgid":           unwrapNil(msgid),
+		"structured_data": sd,
+		"message":         message,
+	}
+	if timestampS != "-" {
+		if ts, err := time.Parse(time.RFC3339Nano, timestampS); err == nil {
+			fields["timestamp"] = ts
+		} else {
+			fields["timestamp"] = timestampS
+		}
+	}
+	return fields, nil
+}
+
+// splitStructuredData separates the STRUCTURED-DATA block ("-" or one or
+// more "[...]" elements) from the trailing MSG. Per RFC5424 a PARAM-VALUE
+// is a quoted string that may itself contain backslash-escaped '"', '\'
+// and ']' characters, so brackets inside a quoted value must not be
+// mistaken for the element's closing bracket.
+func splitStructuredData(s string) (string, string) {
+	if strings.HasPrefix(s, "-") {
+		msg := strings.TrimPrefix(s, "-")
+		return "-", strings.TrimPrefix(msg, " ")
+	}
+	if !strings.HasPrefix(s, "[") {
+		return "-", s
+	}
+
+	depth := 0
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		if inQuotes {
+			switch s[i] {
+			case '\\':
+				i++ // skip the escaped character
+			case '"':
+				inQuotes = false
+			}
+			continue
+		}
+
+		switch s[i] {
+		case '"':
+			inQuotes = true
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				rest := s[i+1:]
+				if strings.HasPrefix(rest, " ") {
+					return s[:i+1], rest[1:]
+				}
+				if rest == "" {
+					return s[:i+1], ""
+				}
+				// more SD elements follow, keep scanning
+			}
+		}
+	}
+	return s, ""
+}
+
+func unwrapNil(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
+
+// rfc3164TimeLayout parses the BSD syslog timestamp after its runs of
+// spaces are collapsed to one; on the wire it is fixed-width, e.g.
+// "Jan  2 15:04:05".
+const rfc3164TimeLayout = "Jan 2 15:04:05"
+
+// parseRFC3164 parses "<PRI>TIMESTAMP HOSTNAME TAG: MSG".
+func parseRFC3164(line string) (map[string]interface{}, error) {
+	facility, severity := 1, 5 // user.notice, the conventional default
+	rest := line
+
+	if f, sv, r, err := parsePriority(line); err == nil {
+		facility, severity, rest = f, sv, r
+	}
+
+	fields := map[string]interface{}{
+		"facility": facility,
+		"severity": severity,
+	}
+
+	if len(rest) >= 15 {
+		tsPart := rest[:15]
+		if ts, err := time.Parse(rfc3164TimeLayout, strings.Join(strings.Fields(tsPart), " ")); err == nil {
+			fields["timestamp"] = ts.AddDate(time.Now().Year(), 0, 0)
+			rest = strings.TrimPrefix(rest[15:], " ")
+		}
+	}
+
+	parts := strings.SplitN(rest, " ", 2)
+	if len(parts) == 2 {
+		fields["hostname"] = parts[0]
+		rest = parts[1]
+	}
+
+	appname, procid, message := rest, "", rest
+	if idx := strings.Index(rest, ": "); idx >= 0 {
+		tag := rest[:idx]
+		message = rest[idx+2:]
+		if o := strings.IndexByte(tag, '['); o >= 0 && strings.HasSuffix(tag, "]") {
+			appname = tag[:o]
+			procid = tag[o+1 : len(tag)-1]
+		} else {
+			appname = tag
+		}
+	}
+
+	fields["appname"] = appname
+	fields["procid"] = procid
+	fields["msgid"] = ""
+	fields["structured_data"] = "-"
+	fields["message"] = message
+
+	return fields, nil
+}
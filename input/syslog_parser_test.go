@@ -0,0 +1,205 @@
+package input
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func newTestReader(s string) *bufio.Reader {
+	return bufio.NewReader(strings.NewReader(s))
+}
+
+func TestParsePriority(t *testing.T) {
+	cases := []struct {
+		name         string
+		line         string
+		wantFacility int
+		wantSeverity int
+		wantRest     string
+		wantErr      bool
+	}{
+		{"user notice", "<13>rest", 1, 5, "rest", false},
+		{"kernel emergency", "<0>rest", 0, 0, "rest", false},
+		{"missing priority", "no priority here", 0, 0, "", true},
+		{"unterminated priority", "<13rest", 0, 0, "", true},
+		{"non-numeric priority", "<abc>rest", 0, 0, "", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			facility, severity, rest, err := parsePriority(c.line)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if facility != c.wantFacility || severity != c.wantSeverity || rest != c.wantRest {
+				t.Fatalf("got (%d, %d, %q), want (%d, %d, %q)", facility, severity, rest, c.wantFacility, c.wantSeverity, c.wantRest)
+			}
+		})
+	}
+}
+
+func TestSplitStructuredData(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      string
+		wantSD  string
+		wantMsg string
+	}{
+		{"nil sd", "- the message", "-", "the message"},
+		{"nil sd no message", "-", "-", ""},
+		{"single element", `[exampleSDID@32473 iut="3"] the message`, `[exampleSDID@32473 iut="3"]`, "the message"},
+		{"multiple elements", `[a@1 x="1"][b@2 y="2"] msg`, `[a@1 x="1"][b@2 y="2"]`, "msg"},
+		{"no message after sd", `[a@1 x="1"]`, `[a@1 x="1"]`, ""},
+		{
+			"escaped closing bracket inside quoted value",
+			`[a@1 x="va\]lue"] the message`,
+			`[a@1 x="va\]lue"]`,
+			"the message",
+		},
+		{
+			"escaped backslash then quote inside quoted value",
+			`[a@1 x="va\\"] the message`,
+			`[a@1 x="va\\"]`,
+			"the message",
+		},
+		{"not structured data", "plain message", "-", "plain message"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sd, msg := splitStructuredData(c.in)
+			if sd != c.wantSD || msg != c.wantMsg {
+				t.Fatalf("got (%q, %q), want (%q, %q)", sd, msg, c.wantSD, c.wantMsg)
+			}
+		})
+	}
+}
+
+func TestParseRFC5424(t *testing.T) {
+	line := `<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - BOM'su root' failed for lonvick on /dev/pts/8`
+	fields, err := parseRFC5424(line)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fields["facility"] != 4 || fields["severity"] != 2 {
+		t.Fatalf("got facility=%v severity=%v, want 4/2", fields["facility"], fields["severity"])
+	}
+	if fields["hostname"] != "mymachine.example.com" {
+		t.Fatalf("got hostname=%v", fields["hostname"])
+	}
+	if fields["appname"] != "su" {
+		t.Fatalf("got appname=%v", fields["appname"])
+	}
+	if fields["procid"] != "" {
+		t.Fatalf("got procid=%v, want empty (nil value)", fields["procid"])
+	}
+	if fields["msgid"] != "ID47" {
+		t.Fatalf("got msgid=%v", fields["msgid"])
+	}
+	if fields["structured_data"] != "-" {
+		t.Fatalf("got structured_data=%v", fields["structured_data"])
+	}
+	if !strings.Contains(fields["message"].(string), "su root") {
+		t.Fatalf("got message=%v", fields["message"])
+	}
+
+	if _, err := parseRFC5424("<34>not rfc5424 at all"); err == nil {
+		t.Fatalf("expected error for non-RFC5424 input")
+	}
+}
+
+func TestParseRFC3164(t *testing.T) {
+	line := "<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick"
+	fields, err := parseRFC3164(line)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fields["facility"] != 4 || fields["severity"] != 2 {
+		t.Fatalf("got facility=%v severity=%v, want 4/2", fields["facility"], fields["severity"])
+	}
+	if fields["hostname"] != "mymachine" {
+		t.Fatalf("got hostname=%v", fields["hostname"])
+	}
+	if fields["appname"] != "su" {
+		t.Fatalf("got appname=%v", fields["appname"])
+	}
+	if fields["procid"] != "123" {
+		t.Fatalf("got procid=%v", fields["procid"])
+	}
+	if fields["message"] != "'su root' failed for lonvick" {
+		t.Fatalf("got message=%v", fields["message"])
+	}
+}
+
+func TestParseSyslogMessage(t *testing.T) {
+	if _, err := parseSyslogMessage(""); err == nil {
+		t.Fatalf("expected error for empty message")
+	}
+
+	fields, err := parseSyslogMessage("<34>1 2003-10-11T22:14:15.003Z host app - - - msg\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fields["hostname"] != "host" {
+		t.Fatalf("expected RFC5424 parse, got fields=%v", fields)
+	}
+
+	fields, err = parseSyslogMessage("<34>Oct 11 22:14:15 host app: msg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fields["hostname"] != "host" {
+		t.Fatalf("expected RFC3164 parse, got fields=%v", fields)
+	}
+}
+
+func TestReadFramedMessage(t *testing.T) {
+	t.Run("octet counting", func(t *testing.T) {
+		r := newTestReader("5 hello6 world!")
+		msg, err := readFramedMessage(r)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if msg != "hello" {
+			t.Fatalf("got %q, want %q", msg, "hello")
+		}
+		msg, err = readFramedMessage(r)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if msg != "world!" {
+			t.Fatalf("got %q, want %q", msg, "world!")
+		}
+	})
+
+	t.Run("non-transparent newline framing", func(t *testing.T) {
+		r := newTestReader("hello\nworld\n")
+		msg, err := readFramedMessage(r)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if msg != "hello\n" {
+			t.Fatalf("got %q, want %q", msg, "hello\n")
+		}
+	})
+
+	t.Run("non-transparent message starting with digits is misframed as octet-counting", func(t *testing.T) {
+		// RFC6587 framing is inherently ambiguous without out-of-band
+		// knowledge of which mode a peer uses: a non-transparent message
+		// that happens to start with "<digits><space>" is read as an
+		// octet count. This test documents that known, currently
+		// unresolved limitation rather than asserting correct framing.
+		r := newTestReader("123 is not a length, it is the message\n")
+		_, err := readFramedMessage(r)
+		if err == nil {
+			t.Fatalf("expected the octet-counting misframe to run past the short input and error, documenting the ambiguity")
+		}
+	})
+}
@@ -0,0 +1,49 @@
+// Package logging provides a global structured logger (zap), used
+// alongside klog so operators can correlate per-event log fields with
+// OpenTelemetry trace/span ids in Jaeger/Tempo.
+package logging
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// L is the global structured logger. It is a no-op logger until Init is
+// called.
+var L *zap.Logger = zap.NewNop()
+
+// Init replaces L with a production (or, if debug is true, development)
+// zap logger.
+func Init(debug bool) error {
+	var (
+		logger *zap.Logger
+		err    error
+	)
+	if debug {
+		logger, err = zap.NewDevelopment()
+	} else {
+		logger, err = zap.NewProduction()
+	}
+	if err != nil {
+		return err
+	}
+	L = logger
+	return nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying logger, retrievable with FromContext.
+func NewContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx by NewContext, or the
+// global logger L if none was stored.
+func FromContext(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return L
+}
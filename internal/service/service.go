@@ -0,0 +1,121 @@
+// Package service lets gohangout install and run itself as a native OS
+// service: systemd on Linux, the Service Control Manager on Windows, and
+// launchd on macOS, via kardianos/service.
+package service
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kardianos/service"
+)
+
+// Config describes how to register gohangout as a service.
+type Config struct {
+	ConfigPath string
+	Worker     int
+	Prometheus string
+}
+
+// program adapts Start/Stop callbacks to service.Interface.
+type program struct {
+	start func()
+	stop  func()
+}
+
+func (p *program) Start(s service.Service) error {
+	go p.start()
+	return nil
+}
+
+func (p *program) Stop(s service.Service) error {
+	p.stop()
+	return nil
+}
+
+// arguments rebuilds the gohangout CLI invocation used by the installed
+// service unit so it starts with the same config/worker/prometheus options
+// the operator passed to `gohangout service install`.
+func arguments(cfg Config) []string {
+	args := []string{"-config", cfg.ConfigPath}
+	if cfg.Worker > 0 {
+		args = append(args, "-worker", fmt.Sprintf("%d", cfg.Worker))
+	}
+	if cfg.Prometheus != "" {
+		args = append(args, "-prometheus", cfg.Prometheus)
+	}
+	return args
+}
+
+// New builds the kardianos/service.Service used to install/control
+// gohangout as a managed service. start is called when the service
+// manager starts gohangout; stop is called on a stop/restart/shutdown
+// request and should trigger the same drain path as SIGTERM.
+func New(cfg Config, start, stop func()) (service.Service, error) {
+	svcConfig := &service.Config{
+		Name:        "gohangout",
+		DisplayName: "gohangout",
+		Description: "gohangout log/event processing pipeline",
+		Arguments:   arguments(cfg),
+	}
+
+	return service.New(&program{start: start, stop: stop}, svcConfig)
+}
+
+// Control runs an install/uninstall/start/stop/restart action, or prints
+// status, against the service registered in cfg. action is one of the
+// service.ControlAction values, plus "status".
+func Control(cfg Config, action string) error {
+	svc, err := New(cfg, func() {}, func() {})
+	if err != nil {
+		return fmt.Errorf("could not build service definition: %w", err)
+	}
+
+	if action == "status" {
+		status, err := svc.Status()
+		if err != nil {
+			return fmt.Errorf("could not query service status: %w", err)
+		}
+		fmt.Println(statusString(status))
+		return nil
+	}
+
+	return service.Control(svc, action)
+}
+
+func statusString(status service.Status) string {
+	switch status {
+	case service.StatusRunning:
+		return "running"
+	case service.StatusStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// Logger returns an io.Writer that forwards each line written to it to the
+// platform-appropriate service log sink (the Windows Event Log, the Linux
+// systemd journal, or the macOS launchd log), so klog output shows up
+// there instead of on a now-detached stdout/stderr when running as a
+// service.
+func Logger(svc service.Service) (io.Writer, error) {
+	l, err := svc.Logger(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &logWriter{logger: l}, nil
+}
+
+type logWriter struct {
+	logger service.Logger
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	line := strings.TrimRight(string(p), "\n")
+	if err := w.logger.Info(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
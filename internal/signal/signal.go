@@ -0,0 +1,29 @@
+package signal
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ListenSignal blocks handling OS signals until the process is told to
+// exit. SIGINT/SIGTERM trigger a full exit, SIGHUP triggers a graceful
+// restart (fork/exec a new process with inherited listeners, then drain
+// and stop this one), and SIGUSR1 triggers an in-place reload of the
+// pipeline from the current config file.
+func ListenSignal(exit, reload, gracefulRestart func()) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1)
+
+	for sig := range c {
+		switch sig {
+		case syscall.SIGINT, syscall.SIGTERM:
+			exit()
+			return
+		case syscall.SIGHUP:
+			gracefulRestart()
+		case syscall.SIGUSR1:
+			reload()
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package tracing wires gohangout's event pipeline into OpenTelemetry, so
+// each event can be correlated with the spans it passes through in
+// Jaeger/Tempo.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+func init() {
+	// Register the W3C propagator globally regardless of whether an
+	// exporter is configured, so input.InputBox can continue an incoming
+	// traceparent (carried in an event's fields by protocols like HTTP or
+	// Kafka headers) into the span it starts per event.
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+// Config configures the OTLP exporter used for tracing.
+type Config struct {
+	// Endpoint is the OTLP collector address, e.g. "localhost:4317" for
+	// gRPC or "localhost:4318" for HTTP.
+	Endpoint string
+	// Protocol is "grpc" or "http". Defaults to "grpc".
+	Protocol string
+	// SamplingRatio is the fraction of traces sampled, in (0, 1]. A value
+	// of 0 or less is treated as 1.
+	SamplingRatio float64
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+	// ResourceAttributes are extra key/value resource attributes, e.g.
+	// {"env": "prod"}.
+	ResourceAttributes map[string]string
+}
+
+// Init configures the global OpenTelemetry tracer provider from cfg and
+// returns a shutdown func that flushes and closes the exporter. Callers
+// should defer shutdown(ctx) on the value returned. If cfg.Endpoint is
+// empty, Init is a no-op and returns a nil shutdown func.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	if cfg.Endpoint == "" {
+		return nil, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not create OTLP exporter: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{semconv.ServiceName(serviceName(cfg))}
+	for k, v := range cfg.ResourceAttributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("could not build OTel resource: %w", err)
+	}
+
+	ratio := cfg.SamplingRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+func serviceName(cfg Config) string {
+	if cfg.ServiceName != "" {
+		return cfg.ServiceName
+	}
+	return "gohangout"
+}
+
+func newExporter(ctx context.Context, cfg Config) (*otlptrace.Exporter, error) {
+	if cfg.Protocol == "http" {
+		return otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(cfg.Endpoint), otlptracehttp.WithInsecure())
+	}
+	return otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(cfg.Endpoint), otlptracegrpc.WithInsecure())
+}
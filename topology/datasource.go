@@ -0,0 +1,52 @@
+package topology
+
+import "context"
+
+// Event is one record flowing through the gohangout topology. It is kept
+// as an alias, rather than a new defined type, so existing code built
+// around map[string]interface{} events needs no conversion.
+type Event = map[string]interface{}
+
+// Mode declares which acquisition methods a DataSource supports.
+type Mode int
+
+const (
+	// ModeStreaming means only StreamingAcquisition is supported (most
+	// network/tailing inputs: syslog, kafka, file tail, ...).
+	ModeStreaming Mode = iota
+	// ModeOneShot means only OneShotAcquisition is supported (pure replay
+	// sources with no notion of "keep watching").
+	ModeOneShot
+	// ModeBoth means the source can either tail or do a single historical
+	// pass, e.g. a file input that can replay-then-exit or replay-then-tail.
+	ModeBoth
+)
+
+// DataSource decouples event acquisition from topology wiring/batching.
+// Each concrete source (file, kafka, stdin, kubernetes pod logs,
+// cloudwatch, syslog, journald, S3 notifications, ...) implements this so
+// InputBox can drive it uniformly, whether it is tailing forever or doing
+// a one-shot replay of historical data (the --oneshot CLI mode).
+type DataSource interface {
+	// Configure applies the source's config block. InputBox does not call
+	// it; a source reaches InputBox already configured.
+	Configure(config map[interface{}]interface{}) error
+
+	// CanRun reports whether the source is ready to acquire events, e.g.
+	// whether it managed to bind its listener or connect to its backend.
+	CanRun() bool
+
+	// Mode declares which of OneShotAcquisition/StreamingAcquisition this
+	// source supports.
+	Mode() Mode
+
+	// OneShotAcquisition reads all currently-available historical data,
+	// sending each event to out, and returns once exhausted (or ctx is
+	// done). Used by `gohangout --oneshot` to reprocess archives.
+	OneShotAcquisition(ctx context.Context, out chan<- Event) error
+
+	// StreamingAcquisition tails the source, sending each event to out as
+	// it arrives, until ctx is done or the source is permanently
+	// exhausted (e.g. EOF with no more data expected).
+	StreamingAcquisition(ctx context.Context, out chan<- Event) error
+}
@@ -0,0 +1,59 @@
+package topology
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Listener is implemented by network-based inputs (TCP/UDP/Syslog/HTTP...)
+// that hold an open listening socket. It lets gohangout.go export the socket's
+// file descriptor for inheritance by a new process during a graceful
+// restart, instead of closing and re-binding it.
+type Listener interface {
+	// ListenerFile returns the underlying *os.File for the listening
+	// socket so it can be passed to a child process via ExtraFiles.
+	ListenerFile() (*os.File, error)
+}
+
+var (
+	inheritedOnce sync.Once
+	inheritedFDs  []*os.File
+	inheritedNext int
+	inheritedMu   sync.Mutex
+)
+
+// listenFDs parses the LISTEN_FDS environment variable set by a parent
+// process doing a graceful restart and returns the inherited files,
+// starting at fd 3 (following the systemd socket activation convention).
+func listenFDs() []*os.File {
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil
+	}
+	files := make([]*os.File, 0, n)
+	for fd := 3; fd < 3+n; fd++ {
+		files = append(files, os.NewFile(uintptr(fd), fmt.Sprintf("listen-fd-%d", fd)))
+	}
+	return files
+}
+
+// NextInheritedListener returns the next inherited listening socket passed
+// down via LISTEN_FDS, or nil if none remain. Inputs call this before
+// binding a fresh listener so a graceful restart hands off sockets in the
+// same order the old process created them in.
+func NextInheritedListener() *os.File {
+	inheritedOnce.Do(func() {
+		inheritedFDs = listenFDs()
+	})
+
+	inheritedMu.Lock()
+	defer inheritedMu.Unlock()
+	if inheritedNext >= len(inheritedFDs) {
+		return nil
+	}
+	f := inheritedFDs[inheritedNext]
+	inheritedNext++
+	return f
+}
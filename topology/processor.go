@@ -0,0 +1,73 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Processor is one stage of the event pipeline: a filter or an output.
+// Process may return zero or more derived events (a filter can drop,
+// pass through unchanged, or fan an event out into several) and an error
+// if the stage failed to handle the event.
+type Processor interface {
+	Process(ctx context.Context, event Event) ([]Event, error)
+}
+
+// ProcessorNode chains Processors into a singly-linked list built by
+// AppendProcessorsToLink. Process threads ctx through the whole chain and
+// wraps each stage in its own child span, so a trace shows every filter
+// and output an event passed through, not just one span for the whole
+// pipeline.
+type ProcessorNode struct {
+	processor  Processor
+	pluginType string // fmt.Sprintf("%T", processor), cached once rather than reflected on every event
+	next       *ProcessorNode
+}
+
+// AppendProcessorsToLink appends p to the chain headed by head (pass nil
+// to start a new chain) and returns the (possibly new) head.
+func AppendProcessorsToLink(head *ProcessorNode, p Processor) *ProcessorNode {
+	node := &ProcessorNode{processor: p, pluginType: fmt.Sprintf("%T", p)}
+	if head == nil {
+		return node
+	}
+	n := head
+	for n.next != nil {
+		n = n.next
+	}
+	n.next = node
+	return head
+}
+
+var processorTracer = otel.Tracer("github.com/childe/gohangout/topology")
+
+// Process runs event through this node, then recurses into the rest of
+// the chain for every event the node's Processor produced. Each stage
+// gets its own child span under ctx, carrying the plugin type, the batch
+// size it produced, and its error status.
+func (n *ProcessorNode) Process(ctx context.Context, event Event) {
+	if n == nil {
+		return
+	}
+
+	spanCtx, span := processorTracer.Start(ctx, "gohangout.process",
+		trace.WithAttributes(attribute.String("plugin.type", n.pluginType)),
+	)
+
+	events, err := n.processor.Process(spanCtx, event)
+	span.SetAttributes(attribute.Int("batch.size", len(events)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+
+	for _, e := range events {
+		n.next.Process(spanCtx, e)
+	}
+}